@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxInFlightRequests is used when no explicit limit is configured.
+const DefaultMaxInFlightRequests = 256
+
+// ConcurrencyLimiter caps the number of requests processed at the same time.
+// Requests above the cap are rejected immediately instead of being queued.
+type ConcurrencyLimiter struct {
+	slots    chan struct{}
+	excluded map[string]struct{}
+}
+
+// NewConcurrencyLimiter creates a limiter allowing maxInFlight concurrent requests.
+// Requests to excludedPaths (e.g. health checks) are never limited.
+func NewConcurrencyLimiter(maxInFlight int, excludedPaths ...string) *ConcurrencyLimiter {
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultMaxInFlightRequests
+	}
+
+	excluded := make(map[string]struct{}, len(excludedPaths))
+	for _, path := range excludedPaths {
+		excluded[path] = struct{}{}
+	}
+
+	return &ConcurrencyLimiter{
+		slots:    make(chan struct{}, maxInFlight),
+		excluded: excluded,
+	}
+}
+
+// InFlight returns the number of requests currently being processed, i.e. the occupied slots.
+func (l *ConcurrencyLimiter) InFlight() int64 {
+	return int64(len(l.slots))
+}
+
+// Handler returns the gin middleware that enforces the limit.
+// When all slots are taken it responds with 503, the SHED code and a Retry-After header.
+func (l *ConcurrencyLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := l.excluded[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"code":    "SHED",
+				"message": "server is overloaded, retry later",
+			})
+			return
+		}
+
+		defer func() { <-l.slots }()
+
+		c.Next()
+	}
+}
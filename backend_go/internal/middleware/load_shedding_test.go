@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestConcurrencyLimiterShedsExcessRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const maxInFlight = 4
+	const excess = 8
+
+	limiter := NewConcurrencyLimiter(maxInFlight, "/ping")
+	started := make(chan struct{}, maxInFlight)
+	release := make(chan struct{})
+
+	router := gin.New()
+	router.Use(limiter.Handler())
+	router.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Unblock the handlers before server.Close waits for them, also when a t.Fatal fires.
+	unblock := sync.OnceFunc(func() { close(release) })
+	defer unblock()
+
+	var blocked sync.WaitGroup
+	for i := 0; i < maxInFlight; i++ {
+		blocked.Add(1)
+		go func() {
+			defer blocked.Done()
+			resp, err := http.Get(server.URL + "/slow")
+			if err != nil {
+				t.Errorf("blocked request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("blocked request status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+		}()
+	}
+	for i := 0; i < maxInFlight; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the limiter slots to fill")
+		}
+	}
+
+	if got := limiter.InFlight(); got != maxInFlight {
+		t.Fatalf("InFlight() = %d, want %d", got, maxInFlight)
+	}
+
+	var shed sync.WaitGroup
+	for i := 0; i < excess; i++ {
+		shed.Add(1)
+		go func() {
+			defer shed.Done()
+			begin := time.Now()
+			resp, err := http.Get(server.URL + "/slow")
+			if err != nil {
+				t.Errorf("excess request failed: %v", err)
+				return
+			}
+			defer resp.Body.Close()
+
+			if elapsed := time.Since(begin); elapsed > time.Second {
+				t.Errorf("excess request took %s, want immediate rejection", elapsed)
+			}
+			if resp.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("excess request status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+			}
+			if got := resp.Header.Get("Retry-After"); got != "1" {
+				t.Errorf("Retry-After = %q, want %q", got, "1")
+			}
+
+			var body map[string]string
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Errorf("decode body: %v", err)
+				return
+			}
+			if body["code"] != "SHED" {
+				t.Errorf("code = %q, want %q", body["code"], "SHED")
+			}
+		}()
+	}
+	shed.Wait()
+
+	resp, err := http.Get(server.URL + "/ping")
+	if err != nil {
+		t.Fatalf("ping request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("ping status while full = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	unblock()
+	blocked.Wait()
+
+	if got := limiter.InFlight(); got != 0 {
+		t.Errorf("InFlight() after release = %d, want 0", got)
+	}
+}
+
+func TestNewConcurrencyLimiterDefaultsNonPositiveLimit(t *testing.T) {
+	limiter := NewConcurrencyLimiter(0)
+
+	if got := cap(limiter.slots); got != DefaultMaxInFlightRequests {
+		t.Errorf("slots = %d, want %d", got, DefaultMaxInFlightRequests)
+	}
+}
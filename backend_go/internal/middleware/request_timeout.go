@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestTimeoutHeader lets callers state how long they are willing to wait, in milliseconds.
+	RequestTimeoutHeader = "X-Request-Timeout"
+	// EffectiveRequestTimeoutHeader echoes the timeout actually applied, in milliseconds.
+	EffectiveRequestTimeoutHeader = "X-Effective-Request-Timeout"
+	// DefaultMaxRequestTimeout is used when no explicit server maximum is configured.
+	DefaultMaxRequestTimeout = 30 * time.Second
+)
+
+// RequestTimeout returns a middleware that honors the X-Request-Timeout header.
+// The requested value is clamped to maxTimeout, invalid values fall back to maxTimeout,
+// and the request context gets the resulting deadline. Requests without the header are untouched.
+// If the deadline is hit before the handler writes anything, the response is 504 with the TIMEOUT code.
+// A response the handler has already written is left as is, so handlers that return their own error
+// on cancellation should report it through AbortIfDeadlineExceeded instead of writing it themselves.
+func RequestTimeout(maxTimeout time.Duration) gin.HandlerFunc {
+	if maxTimeout <= 0 {
+		maxTimeout = DefaultMaxRequestTimeout
+	}
+
+	return func(c *gin.Context) {
+		header := c.GetHeader(RequestTimeoutHeader)
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		timeout := parseRequestTimeout(header, maxTimeout)
+		c.Header(EffectiveRequestTimeoutHeader, strconv.FormatInt(timeout.Milliseconds(), 10))
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			abortWithTimeout(c)
+		}
+	}
+}
+
+// AbortIfDeadlineExceeded writes the 504 TIMEOUT response when err is caused by an exceeded deadline.
+// It returns false for any other error, leaving the response to the caller.
+func AbortIfDeadlineExceeded(c *gin.Context, err error) bool {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	abortWithTimeout(c)
+	return true
+}
+
+func abortWithTimeout(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+		"code":    "TIMEOUT",
+		"message": "request deadline exceeded",
+	})
+}
+
+// parseRequestTimeout converts the header value in milliseconds to a duration within (0, maxTimeout].
+func parseRequestTimeout(value string, maxTimeout time.Duration) time.Duration {
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || millis <= 0 || millis > maxTimeout.Milliseconds() {
+		return maxTimeout
+	}
+
+	return time.Duration(millis) * time.Millisecond
+}
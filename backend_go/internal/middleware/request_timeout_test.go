@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseRequestTimeout(t *testing.T) {
+	maxTimeout := 5 * time.Second
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "valid", value: "1500", want: 1500 * time.Millisecond},
+		{name: "exactly max", value: "5000", want: maxTimeout},
+		{name: "not a number", value: "abc", want: maxTimeout},
+		{name: "negative", value: "-5", want: maxTimeout},
+		{name: "zero", value: "0", want: maxTimeout},
+		{name: "over max", value: "5001", want: maxTimeout},
+		{name: "int64 overflow", value: "99999999999999999999", want: maxTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRequestTimeout(tt.value, maxTimeout); got != tt.want {
+				t.Errorf("parseRequestTimeout(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func newTimeoutRouter(maxTimeout time.Duration, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestTimeout(maxTimeout))
+	router.GET("/test", handler)
+
+	return router
+}
+
+func serve(router *gin.Engine, timeoutHeader string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	if timeoutHeader != "" {
+		req.Header.Set(RequestTimeoutHeader, timeoutHeader)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func assertTimeoutResponse(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusGatewayTimeout)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["code"] != "TIMEOUT" {
+		t.Errorf("code = %q, want %q", body["code"], "TIMEOUT")
+	}
+}
+
+func TestRequestTimeoutEchoesEffectiveTimeout(t *testing.T) {
+	router := newTimeoutRouter(time.Second, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "250", want: "250"},
+		{header: "5000", want: "1000"},
+		{header: "abc", want: "1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			w := serve(router, tt.header)
+
+			if got := w.Header().Get(EffectiveRequestTimeoutHeader); got != tt.want {
+				t.Errorf("%s = %q, want %q", EffectiveRequestTimeoutHeader, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutWithoutHeaderLeavesContextUntouched(t *testing.T) {
+	hasDeadline := true
+	router := newTimeoutRouter(time.Second, func(c *gin.Context) {
+		_, hasDeadline = c.Request.Context().Deadline()
+		c.Status(http.StatusOK)
+	})
+
+	w := serve(router, "")
+
+	if hasDeadline {
+		t.Error("request context has a deadline, want none")
+	}
+	if got := w.Header().Get(EffectiveRequestTimeoutHeader); got != "" {
+		t.Errorf("%s = %q, want empty", EffectiveRequestTimeoutHeader, got)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRequestTimeoutRespondsWithGatewayTimeout(t *testing.T) {
+	router := newTimeoutRouter(time.Second, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	assertTimeoutResponse(t, serve(router, "20"))
+}
+
+func TestRequestTimeoutHandlerReportsDeadlineError(t *testing.T) {
+	router := newTimeoutRouter(time.Second, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		err := fmt.Errorf("query tasks: %w", c.Request.Context().Err())
+
+		if AbortIfDeadlineExceeded(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	})
+
+	assertTimeoutResponse(t, serve(router, "20"))
+}
+
+func TestAbortIfDeadlineExceededIgnoresOtherErrors(t *testing.T) {
+	router := newTimeoutRouter(time.Second, func(c *gin.Context) {
+		if AbortIfDeadlineExceeded(c, errors.New("boom")) {
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+	})
+
+	if w := serve(router, "20"); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,100 @@
+package static
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// hashedAssetsPrefix matches build.assetsDir in frontend/vite.config.ts.
+	// Files there carry a content hash in their names and never change.
+	hashedAssetsPrefix = "/js/"
+	indexFile          = "/index.html"
+
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	noCacheControl        = "no-cache"
+)
+
+// RegisterSPA serves the frontend build from dir for every route not registered on the engine.
+// Existing files are served as is, other GET/HEAD paths fall back to index.html (SPA history routing),
+// and unknown /api paths get a JSON 404 instead of the page. Missing hashed assets also get a 404,
+// so browsers holding chunk names from a previous build don't receive HTML for a script.
+// A non-empty basePath mounts the frontend under that prefix; paths outside it get a JSON 404.
+// The frontend must then be built with the same base (vite build --base=<basePath>/),
+// otherwise index.html references assets at the root and they are not found.
+func RegisterSPA(r *gin.Engine, basePath string, dir string) {
+	fs := http.Dir(dir)
+
+	r.NoRoute(func(c *gin.Context) {
+		requestPath, ok := stripBasePath(path.Clean("/"+c.Request.URL.Path), basePath)
+		if !ok || isAPIPath(requestPath) || (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) {
+			writeNotFound(c)
+			return
+		}
+
+		if requestPath != "/" && serveFile(c, fs, requestPath) {
+			return
+		}
+
+		if strings.HasPrefix(requestPath, hashedAssetsPrefix) {
+			writeNotFound(c)
+			return
+		}
+
+		if !serveFile(c, fs, indexFile) {
+			writeNotFound(c)
+		}
+	})
+}
+
+// stripBasePath returns requestPath relative to basePath, or false if it lies outside of it.
+func stripBasePath(requestPath string, basePath string) (string, bool) {
+	if basePath == "" {
+		return requestPath, true
+	}
+	if requestPath == basePath {
+		return "/", true
+	}
+	if strings.HasPrefix(requestPath, basePath+"/") {
+		return strings.TrimPrefix(requestPath, basePath), true
+	}
+
+	return "", false
+}
+
+func writeNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"code":    "NOT_FOUND",
+		"message": "resource not found",
+	})
+}
+
+func isAPIPath(requestPath string) bool {
+	return requestPath == "/api" || strings.HasPrefix(requestPath, "/api/")
+}
+
+// serveFile writes the named regular file with its cache policy. It returns false if there is no such file.
+func serveFile(c *gin.Context, fs http.FileSystem, name string) bool {
+	file, err := fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	if strings.HasPrefix(name, hashedAssetsPrefix) {
+		c.Header("Cache-Control", immutableCacheControl)
+	} else {
+		c.Header("Cache-Control", noCacheControl)
+	}
+
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), file)
+	return true
+}
@@ -0,0 +1,207 @@
+package static
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	indexBody = "<html>index</html>"
+	assetBody = "console.log('app')"
+	assetPath = "/js/app-3f9a1c2b.js"
+)
+
+// newStaticDir creates a build directory with index.html and a hashed asset.
+func newStaticDir(t *testing.T, withIndex bool) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "js"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(assetPath)), []byte(assetBody), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if withIndex {
+		if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(indexBody), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func newSPARouter(basePath string, dir string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Group(basePath).GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	RegisterSPA(router, basePath, dir)
+
+	return router
+}
+
+func request(router *gin.Engine, method string, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func assertJSONNotFound(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", w.Body.String(), err)
+	}
+	if body["code"] != "NOT_FOUND" {
+		t.Errorf("code = %q, want %q", body["code"], "NOT_FOUND")
+	}
+}
+
+func TestRegisterSPAFallsBackToIndex(t *testing.T) {
+	router := newSPARouter("", newStaticDir(t, true))
+
+	for _, path := range []string{"/", "/tasks/1"} {
+		t.Run(path, func(t *testing.T) {
+			w := request(router, http.MethodGet, path)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if w.Body.String() != indexBody {
+				t.Errorf("body = %q, want index.html", w.Body.String())
+			}
+			if got := w.Header().Get("Cache-Control"); got != noCacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, noCacheControl)
+			}
+		})
+	}
+}
+
+func TestRegisterSPAServesHashedAssetsAsImmutable(t *testing.T) {
+	router := newSPARouter("", newStaticDir(t, true))
+
+	w := request(router, http.MethodGet, assetPath)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w.Body.String() != assetBody {
+		t.Errorf("body = %q, want the asset", w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); got != immutableCacheControl {
+		t.Errorf("Cache-Control = %q, want %q", got, immutableCacheControl)
+	}
+}
+
+func TestRegisterSPAReturnsJSONNotFoundForMissingHashedAsset(t *testing.T) {
+	router := newSPARouter("", newStaticDir(t, true))
+
+	assertJSONNotFound(t, request(router, http.MethodGet, "/js/missing-abc.js"))
+}
+
+func TestRegisterSPAReturnsJSONNotFoundForAPI(t *testing.T) {
+	router := newSPARouter("", newStaticDir(t, true))
+
+	for _, path := range []string{"/api/v1/x", "/api"} {
+		t.Run(path, func(t *testing.T) {
+			assertJSONNotFound(t, request(router, http.MethodGet, path))
+		})
+	}
+}
+
+func TestRegisterSPAReturnsJSONNotFoundForNonGet(t *testing.T) {
+	router := newSPARouter("", newStaticDir(t, true))
+
+	assertJSONNotFound(t, request(router, http.MethodPost, "/unknown"))
+}
+
+func TestRegisterSPADoesNotShadowRoutes(t *testing.T) {
+	router := newSPARouter("", newStaticDir(t, true))
+
+	w := request(router, http.MethodGet, "/ping")
+
+	if w.Code != http.StatusOK || w.Body.String() != "pong" {
+		t.Errorf("GET /ping = %d %q, want 200 %q", w.Code, w.Body.String(), "pong")
+	}
+}
+
+func TestRegisterSPAWithoutIndexReturnsJSONNotFound(t *testing.T) {
+	router := newSPARouter("", newStaticDir(t, false))
+
+	assertJSONNotFound(t, request(router, http.MethodGet, "/tasks/1"))
+}
+
+func TestStripBasePath(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestPath string
+		basePath    string
+		want        string
+		wantOK      bool
+	}{
+		{name: "no base path", requestPath: "/tasks/1", basePath: "", want: "/tasks/1", wantOK: true},
+		{name: "exact base", requestPath: "/taskapp", basePath: "/taskapp", want: "/", wantOK: true},
+		{name: "base with slash", requestPath: "/taskapp/", basePath: "/taskapp", want: "/", wantOK: true},
+		{name: "nested path", requestPath: "/taskapp/tasks/1", basePath: "/taskapp", want: "/tasks/1", wantOK: true},
+		{name: "lookalike prefix", requestPath: "/taskappX", basePath: "/taskapp", wantOK: false},
+		{name: "lookalike segment", requestPath: "/taskappX/tasks", basePath: "/taskapp", wantOK: false},
+		{name: "outside base", requestPath: "/js/app.js", basePath: "/taskapp", wantOK: false},
+		{name: "root outside base", requestPath: "/", basePath: "/taskapp", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := stripBasePath(tt.requestPath, tt.basePath)
+
+			if ok != tt.wantOK {
+				t.Fatalf("stripBasePath(%q, %q) ok = %v, want %v", tt.requestPath, tt.basePath, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("stripBasePath(%q, %q) = %q, want %q", tt.requestPath, tt.basePath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterSPAUnderBasePath(t *testing.T) {
+	router := newSPARouter("/taskapp", newStaticDir(t, true))
+
+	tests := []struct {
+		path     string
+		wantBody string
+	}{
+		{path: "/taskapp", wantBody: indexBody},
+		{path: "/taskapp/tasks/1", wantBody: indexBody},
+		{path: "/taskapp" + assetPath, wantBody: assetBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := request(router, http.MethodGet, tt.path)
+
+			if w.Code != http.StatusOK || w.Body.String() != tt.wantBody {
+				t.Errorf("GET %s = %d %q, want 200 %q", tt.path, w.Code, w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	for _, path := range []string{"/", "/tasks/1", assetPath, "/taskapp/api/v1/x"} {
+		t.Run(path, func(t *testing.T) {
+			assertJSONNotFound(t, request(router, http.MethodGet, path))
+		})
+	}
+}
@@ -1,18 +1,119 @@
 package main
 
 import (
+	"expvar"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"go_backend/internal/middleware"
+	"go_backend/internal/static"
+	"log"
 	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// inFlightLimiter is the limiter of the router built last; its gauge is published via expvar.
+var inFlightLimiter atomic.Pointer[middleware.ConcurrencyLimiter]
+
+func init() {
+	expvar.Publish("http_in_flight_requests", expvar.Func(func() any {
+		if limiter := inFlightLimiter.Load(); limiter != nil {
+			return limiter.InFlight()
+		}
+		return int64(0)
+	}))
+}
+
 func main() {
+	basePath, err := parseBasePath(os.Getenv("BASE_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := newRouter(basePath, getMaxInFlightRequests(), getMaxRequestTimeout(), os.Getenv("STATIC_DIR"))
+
+	r.Run() // listen and serve on 0.0.0.0:8080
+}
+
+// newRouter registers the middleware and all routes under basePath.
+// The frontend build is served from staticDir when it is not empty.
+// Runtime variables, including the http_in_flight_requests gauge, are served at /debug/vars.
+func newRouter(basePath string, maxInFlight int, maxRequestTimeout time.Duration, staticDir string) *gin.Engine {
 	r := gin.Default()
 
-	r.GET("/ping", func(c *gin.Context) {
+	limiter := middleware.NewConcurrencyLimiter(maxInFlight, basePath+"/ping", basePath+"/debug/vars")
+	inFlightLimiter.Store(limiter)
+	r.Use(limiter.Handler())
+	r.Use(middleware.RequestTimeout(maxRequestTimeout))
+
+	root := r.Group(basePath)
+
+	root.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"message": "pong",
 		})
 	})
+	root.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 
-	r.Run() // listen and serve on 0.0.0.0:8080
+	if staticDir != "" {
+		static.RegisterSPA(r, basePath, staticDir)
+	}
+
+	return r
+}
+
+// parseBasePath validates BASE_PATH, the prefix all routes are served under when running behind an ingress.
+// It must start with "/", must not end with "/" and must already be clean (no "//", "." or ".." segments),
+// because the limiter exclusion and the SPA fallback compare request paths against it as is.
+// An empty value serves routes from the root.
+func parseBasePath(basePath string) (string, error) {
+	if basePath == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(basePath, "/") || strings.HasSuffix(basePath, "/") {
+		return "", fmt.Errorf("invalid BASE_PATH %q: must start with \"/\" and must not end with \"/\"", basePath)
+	}
+
+	if basePath != path.Clean(basePath) {
+		return "", fmt.Errorf("invalid BASE_PATH %q: must be a clean path such as %q", basePath, path.Clean(basePath))
+	}
+
+	return basePath, nil
+}
+
+// getMaxInFlightRequests reads MAX_IN_FLIGHT_REQUESTS, falling back to the default when unset or invalid.
+func getMaxInFlightRequests() int {
+	value := os.Getenv("MAX_IN_FLIGHT_REQUESTS")
+	if value == "" {
+		return middleware.DefaultMaxInFlightRequests
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		log.Printf("invalid MAX_IN_FLIGHT_REQUESTS %q, using default %d", value, middleware.DefaultMaxInFlightRequests)
+		return middleware.DefaultMaxInFlightRequests
+	}
+
+	return limit
+}
+
+// getMaxRequestTimeout reads MAX_REQUEST_TIMEOUT_MS, falling back to the default when unset or invalid.
+func getMaxRequestTimeout() time.Duration {
+	value := os.Getenv("MAX_REQUEST_TIMEOUT_MS")
+	if value == "" {
+		return middleware.DefaultMaxRequestTimeout
+	}
+
+	millis, err := strconv.Atoi(value)
+	if err != nil || millis <= 0 {
+		log.Printf("invalid MAX_REQUEST_TIMEOUT_MS %q, using default %s", value, middleware.DefaultMaxRequestTimeout)
+		return middleware.DefaultMaxRequestTimeout
+	}
+
+	return time.Duration(millis) * time.Millisecond
 }
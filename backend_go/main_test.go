@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go_backend/internal/middleware"
+)
+
+func TestParseBasePath(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ""},
+		{value: "/taskapp", want: "/taskapp"},
+		{value: "/apps/taskapp", want: "/apps/taskapp"},
+		{value: "taskapp", wantErr: true},
+		{value: "/taskapp/", wantErr: true},
+		{value: "/", wantErr: true},
+		{value: "/a//b", wantErr: true},
+		{value: "/a/./b", wantErr: true},
+		{value: "/a/../b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseBasePath(tt.value)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseBasePath(%q) = %q, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseBasePath(%q) error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseBasePath(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouterServesRoutesUnderBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newRouter("/taskapp", 1, time.Second, "")
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/taskapp/ping", want: http.StatusOK},
+		{path: "/ping", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouterExcludesPrefixedPingFromLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newRouter("/taskapp", 1, time.Second, "")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router.Group("/taskapp").GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if resp, err := http.Get(server.URL + "/taskapp/slow"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the limiter slot to fill")
+	}
+	defer func() {
+		close(release)
+		<-done
+	}()
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/taskapp/ping", want: http.StatusOK},
+		{path: "/taskapp/slow", want: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", tt.path, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s while limiter is full = %d, want %d", tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestNewRouterPublishesInFlightGauge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newRouter("", 1, time.Second, "")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router.GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if resp, err := http.Get(server.URL + "/slow"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the limiter slot to fill")
+	}
+	defer func() {
+		close(release)
+		<-done
+	}()
+
+	resp, err := http.Get(server.URL + "/debug/vars")
+	if err != nil {
+		t.Fatalf("GET /debug/vars: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /debug/vars while limiter is full = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatalf("decode /debug/vars: %v", err)
+	}
+	if got := string(vars["http_in_flight_requests"]); got != "1" {
+		t.Errorf("http_in_flight_requests = %s, want 1", got)
+	}
+}
+
+func TestGetMaxRequestTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: middleware.DefaultMaxRequestTimeout},
+		{value: "1500", want: 1500 * time.Millisecond},
+		{value: "abc", want: middleware.DefaultMaxRequestTimeout},
+		{value: "0", want: middleware.DefaultMaxRequestTimeout},
+		{value: "-5", want: middleware.DefaultMaxRequestTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("MAX_REQUEST_TIMEOUT_MS", tt.value)
+
+			if got := getMaxRequestTimeout(); got != tt.want {
+				t.Errorf("getMaxRequestTimeout() with %q = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouterAppliesRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("MAX_REQUEST_TIMEOUT_MS", "1000")
+	router := newRouter("", 1, getMaxRequestTimeout(), "")
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "250", want: "250"},
+		{header: "5000", want: "1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.Header.Set(middleware.RequestTimeoutHeader, tt.header)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET /ping = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get(middleware.EffectiveRequestTimeoutHeader); got != tt.want {
+				t.Errorf("%s = %q, want %q", middleware.EffectiveRequestTimeoutHeader, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouterTimedOutRequestReleasesLimiterSlot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newRouter("", 1, time.Second, "")
+	router.GET("/wait", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/wait", nil)
+		req.Header.Set(middleware.RequestTimeoutHeader, "20")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("request %d: GET /wait = %d, want %d", i+1, w.Code, http.StatusGatewayTimeout)
+		}
+	}
+
+	if got := inFlightLimiter.Load().InFlight(); got != 0 {
+		t.Errorf("InFlight() after timeouts = %d, want 0", got)
+	}
+}